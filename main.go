@@ -1,15 +1,48 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/dingtalk-alert-server/server"
 )
 
 func main() {
+	templateDir := flag.String("template-dir", "", "directory of *.tmpl overrides for alert notifications (falls back to the built-in templates)")
+	stateFile := flag.String("state-file", "", "path to a JSON file used to persist alert dedup state across restarts (defaults to in-memory, non-persistent)")
+	repeatInterval := flag.Duration("repeat-interval", 4*time.Hour, "how long a firing alert is suppressed before it is re-sent")
+	configFile := flag.String("config", "", "path to a YAML file of receivers and a routing tree, so callers can pass ?receiver=<name> instead of raw webhook URLs")
+	groupWait := flag.Duration("group-wait", 0, "how long to buffer alerts for a group_key before sending them as one batched notification (0 disables batching and sends every request immediately)")
+	groupInterval := flag.Duration("group-interval", 5*time.Minute, "minimum time between two batched flushes of the same group_key, once --group-wait is set")
+	flag.Parse()
+
+	server.SetTemplateDir(*templateDir)
+	server.SetRepeatInterval(*repeatInterval)
+	server.SetGroupWait(*groupWait)
+	server.SetGroupInterval(*groupInterval)
+
+	if *stateFile != "" {
+		store, err := server.NewFileStateStore(*stateFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		server.SetStateStore(store)
+	}
+
+	if *configFile != "" {
+		if err := server.LoadConfig(*configFile); err != nil {
+			log.Fatal(err)
+		}
+		server.WatchConfigReload()
+	}
+
 	log.Print("[INFO] Dingtalk server start")
 	http.HandleFunc("/dingtalk", server.ReceiveAndSend)
+	http.Handle("/metrics", server.MetricsHandler())
+	http.HandleFunc("/healthz", server.HealthzHandler)
+	http.HandleFunc("/readyz", server.ReadyzHandler)
 
 	err := http.ListenAndServe(":9090", nil)
 	if err != nil {
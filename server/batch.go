@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// groupWait and groupInterval mirror Alertmanager's own route-level batching: the
+// first alert seen for a group_key starts a groupWait timer, and any further alerts
+// for that group arriving before it fires are merged into a single notification
+// instead of one per request; after a flush, the group won't flush again for at
+// least groupInterval. Both default to zero, which disables batching and keeps the
+// legacy behavior of sending every request's alerts immediately.
+var (
+	groupWait     time.Duration
+	groupInterval time.Duration
+)
+
+// SetGroupWait configures how long to buffer alerts for a group_key before flushing
+// them as one batched notification. Zero (the default) sends every request
+// immediately, with no batching.
+func SetGroupWait(d time.Duration) {
+	groupWait = d
+}
+
+// SetGroupInterval configures the minimum time between two flushes of the same
+// group_key, once batching is enabled via SetGroupWait.
+func SetGroupInterval(d time.Duration) {
+	groupInterval = d
+}
+
+// pendingBatch accumulates alerts for one group_key while its flush timer is running.
+type pendingBatch struct {
+	alerts  []Alert
+	message Message
+	targets []sendTarget
+	timer   *time.Timer
+}
+
+var (
+	batchMu          sync.Mutex
+	batches          = map[string]*pendingBatch{}
+	lastFlushByGroup = map[string]time.Time{}
+)
+
+// enqueueOrSend buffers alertMessage's alerts for groupKey when batching is enabled,
+// returning false once they've been queued rather than sent - the caller should send
+// immediately itself only when this returns true. Batching is a no-op (always
+// returns true) when groupWait is zero or groupKey is empty, which is the default.
+func enqueueOrSend(groupKey string, targets []sendTarget, alertMessage Message) bool {
+	if groupWait <= 0 || groupKey == "" {
+		return true
+	}
+
+	detached := make([]sendTarget, len(targets))
+	for i, target := range targets {
+		// The request's context is canceled once ReceiveAndSend returns, but a
+		// batched flush happens later on its own timer, so it needs a context
+		// that outlives the request while still carrying e.g. the per-receiver
+		// template_dir override.
+		detached[i] = sendTarget{name: target.name, notifier: target.notifier, ctx: context.WithoutCancel(target.ctx)}
+	}
+
+	batchMu.Lock()
+	defer batchMu.Unlock()
+
+	pending, ok := batches[groupKey]
+	if !ok {
+		pending = &pendingBatch{}
+		batches[groupKey] = pending
+	}
+	pending.alerts = append(pending.alerts, alertMessage.Alerts...)
+	pending.message = alertMessage
+	pending.targets = mergeTargets(pending.targets, detached)
+
+	if pending.timer != nil {
+		return false
+	}
+
+	wait := groupWait
+	if since := time.Since(lastFlushByGroup[groupKey]); since < groupInterval {
+		if remaining := groupInterval - since; remaining > wait {
+			wait = remaining
+		}
+	}
+	pending.timer = time.AfterFunc(wait, func() { flushBatch(groupKey) })
+	return false
+}
+
+// mergeTargets unions existing and additional by target name instead of letting a
+// later call clobber an earlier one's targets: two requests landing in the same
+// groupWait window for the same group_id can resolve to different destinations
+// (different ?receiver=, a differing ?notifier= list, or a route-tree match that
+// changed between calls), and the first request's destination must still get
+// notified when the batch flushes. A name that already has a target keeps the one
+// it saw first; if the new one resolves to a differently configured notifier, that
+// mismatch is logged rather than silently preferring either side.
+func mergeTargets(existing, additional []sendTarget) []sendTarget {
+	merged := make([]sendTarget, len(existing), len(existing)+len(additional))
+	copy(merged, existing)
+
+	byName := make(map[string]sendTarget, len(existing))
+	for _, target := range existing {
+		byName[target.name] = target
+	}
+
+	for _, target := range additional {
+		prev, ok := byName[target.name]
+		if !ok {
+			byName[target.name] = target
+			merged = append(merged, target)
+			continue
+		}
+		if !reflect.DeepEqual(prev.notifier, target.notifier) {
+			logger.Warnw("batched target resolved to a different notifier config mid-window, keeping the first one", "target", target.name)
+		}
+	}
+
+	return merged
+}
+
+// flushBatch sends every alert buffered for groupKey as a single notification per
+// target, the same way ReceiveAndSend would have sent them immediately.
+func flushBatch(groupKey string) {
+	batchMu.Lock()
+	pending, ok := batches[groupKey]
+	if ok {
+		delete(batches, groupKey)
+		lastFlushByGroup[groupKey] = time.Now()
+	}
+	batchMu.Unlock()
+	if !ok {
+		return
+	}
+
+	message := pending.message
+	message.Alerts = pending.alerts
+
+	for _, target := range pending.targets {
+		start := time.Now()
+		err := target.notifier.Notify(target.ctx, message)
+		sendDuration.WithLabelValues(target.name).Observe(time.Since(start).Seconds())
+		if err != nil {
+			logger.Errorw("failed to send batched alert", "error", err, "notifier", target.name, "group_key", groupKey)
+			continue
+		}
+		alertsForwardedTotal.WithLabelValues(target.name).Inc()
+	}
+}
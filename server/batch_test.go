@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingNotifier captures every Message it's asked to send, for assertions in
+// batching tests.
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, message Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func (n *recordingNotifier) received() []Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Message(nil), n.messages...)
+}
+
+func resetBatchStateForTest(t *testing.T) {
+	t.Helper()
+	prevWait, prevInterval := groupWait, groupInterval
+	batchMu.Lock()
+	batches = map[string]*pendingBatch{}
+	lastFlushByGroup = map[string]time.Time{}
+	batchMu.Unlock()
+
+	t.Cleanup(func() {
+		groupWait, groupInterval = prevWait, prevInterval
+		batchMu.Lock()
+		batches = map[string]*pendingBatch{}
+		lastFlushByGroup = map[string]time.Time{}
+		batchMu.Unlock()
+	})
+}
+
+func TestEnqueueOrSendDisabledByDefault(t *testing.T) {
+	resetBatchStateForTest(t)
+	SetGroupWait(0)
+
+	n := &recordingNotifier{}
+	targets := []sendTarget{{name: "test", notifier: n, ctx: context.Background()}}
+
+	if !enqueueOrSend("group-1", targets, Message{}) {
+		t.Fatalf("expected enqueueOrSend to return true (send immediately) when groupWait is 0")
+	}
+}
+
+func TestEnqueueOrSendBatchesMultipleRequestsIntoOneFlush(t *testing.T) {
+	resetBatchStateForTest(t)
+	SetGroupWait(20 * time.Millisecond)
+	SetGroupInterval(0)
+
+	n := &recordingNotifier{}
+	targets := []sendTarget{{name: "test", notifier: n, ctx: context.Background()}}
+
+	first := Message{CommonLabels: map[string]string{"group_id": "group-1"}, Alerts: []Alert{{Status: "firing", Labels: map[string]string{"alertname": "A"}}}}
+	second := Message{CommonLabels: map[string]string{"group_id": "group-1"}, Alerts: []Alert{{Status: "firing", Labels: map[string]string{"alertname": "B"}}}}
+
+	if enqueueOrSend("group-1", targets, first) {
+		t.Fatalf("expected the first alert in a group to be queued, not sent immediately")
+	}
+	if enqueueOrSend("group-1", targets, second) {
+		t.Fatalf("expected the second alert in the same window to be queued, not sent immediately")
+	}
+
+	deadline := time.After(time.Second)
+	for len(n.received()) == 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for the batch to flush")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	messages := n.received()
+	if len(messages) != 1 {
+		t.Fatalf("expected exactly one flushed notification, got %d", len(messages))
+	}
+	if len(messages[0].Alerts) != 2 {
+		t.Fatalf("expected both alerts to be merged into the one flush, got %d", len(messages[0].Alerts))
+	}
+}
+
+// TestEnqueueOrSendKeepsBothTargetsWhenTheyDifferAcrossCalls is a regression test:
+// the first request in a group_wait window must still get notified even if a later
+// request in the same window resolves to a different (or additional) target.
+func TestEnqueueOrSendKeepsBothTargetsWhenTheyDifferAcrossCalls(t *testing.T) {
+	resetBatchStateForTest(t)
+	SetGroupWait(20 * time.Millisecond)
+	SetGroupInterval(0)
+
+	first := &recordingNotifier{}
+	second := &recordingNotifier{}
+
+	msg := Message{CommonLabels: map[string]string{"group_id": "group-1"}, Alerts: []Alert{{Status: "firing", Labels: map[string]string{"alertname": "A"}}}}
+
+	if enqueueOrSend("group-1", []sendTarget{{name: "first", notifier: first, ctx: context.Background()}}, msg) {
+		t.Fatalf("expected the first request to be queued, not sent immediately")
+	}
+	if enqueueOrSend("group-1", []sendTarget{{name: "second", notifier: second, ctx: context.Background()}}, msg) {
+		t.Fatalf("expected the second request to be queued, not sent immediately")
+	}
+
+	deadline := time.After(time.Second)
+	for len(first.received()) == 0 || len(second.received()) == 0 {
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for the batch to flush to both targets")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	if len(first.received()) != 1 {
+		t.Fatalf("expected the first request's target to still be notified, got %d messages", len(first.received()))
+	}
+	if len(second.received()) != 1 {
+		t.Fatalf("expected the second request's target to be notified, got %d messages", len(second.received()))
+	}
+}
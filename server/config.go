@@ -0,0 +1,215 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReceiverConfig describes one named notification target: which notifier backend to
+// use and the parameters it needs, keeping secrets like webhook URLs and signing
+// secrets out of the query string.
+type ReceiverConfig struct {
+	Name               string              `yaml:"receiver"`
+	Notifier           string              `yaml:"notifier"`
+	Webhook            string              `yaml:"webhook"`
+	Secret             string              `yaml:"secret"`
+	AtMobiles          []string            `yaml:"at_mobiles"`
+	AtAll              bool                `yaml:"at_all"`
+	Insecure           bool                `yaml:"insecure"`
+	TemplateDir        string              `yaml:"template_dir"`
+	RateLimitPerMinute float64             `yaml:"rate_limit_per_minute"`
+	Params             map[string][]string `yaml:"params"`
+}
+
+// Route is one node of the routing tree, matched against an alert's labels the same
+// way Alertmanager's route/match/match_re works: children are tried in list order,
+// the deepest matching route on the first path that yields a receiver wins, falling
+// back to its parent's Receiver.
+type Route struct {
+	Receiver string            `yaml:"receiver"`
+	Match    map[string]string `yaml:"match"`
+	MatchRE  map[string]string `yaml:"match_re"`
+	Routes   []*Route          `yaml:"routes"`
+}
+
+// Config is the top-level --config file: a list of receivers and the route tree used
+// to pick one when the request doesn't name a receiver explicitly.
+type Config struct {
+	Receivers []ReceiverConfig `yaml:"receivers"`
+	Route     *Route           `yaml:"route"`
+}
+
+var (
+	configMu        sync.RWMutex
+	config          *Config
+	configPath      string
+	receiversByName map[string]ReceiverConfig
+)
+
+// LoadConfig reads and parses the receivers/routing config at path, atomically
+// replacing whatever was loaded before. The path is remembered so a later SIGHUP
+// (see WatchConfigReload) can reload it.
+func LoadConfig(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	byName := make(map[string]ReceiverConfig, len(cfg.Receivers))
+	for _, r := range cfg.Receivers {
+		byName[r.Name] = r
+	}
+
+	configMu.Lock()
+	config = cfg
+	configPath = path
+	receiversByName = byName
+	configMu.Unlock()
+
+	logger.Infow("config loaded", "path", path, "receivers", len(cfg.Receivers))
+	return nil
+}
+
+// WatchConfigReload re-reads configPath whenever the process receives SIGHUP, so
+// operators can change receivers and routes without restarting the bridge.
+func WatchConfigReload() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	go func() {
+		for range sighup {
+			configMu.RLock()
+			path := configPath
+			configMu.RUnlock()
+
+			if path == "" {
+				continue
+			}
+			if err := LoadConfig(path); err != nil {
+				logger.Errorw("failed to reload config on SIGHUP", "error", err, "path", path)
+			}
+		}
+	}()
+}
+
+func receiverByName(name string) (ReceiverConfig, bool) {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	rc, ok := receiversByName[name]
+	return rc, ok
+}
+
+// resolveReceiverName picks which receiver to notify: an explicit ?receiver= query
+// parameter wins, then the Receiver field Alertmanager already set from its own
+// routing tree, then this bridge's own route tree matched against the alert's
+// labels. It returns "" when none of those apply, so ReceiveAndSend can fall back to
+// the legacy ?webhook=/?notifier= flow.
+func resolveReceiverName(alertMessage Message, explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if alertMessage.Receiver != "" {
+		if _, ok := receiverByName(alertMessage.Receiver); ok {
+			return alertMessage.Receiver
+		}
+	}
+
+	configMu.RLock()
+	cfg := config
+	configMu.RUnlock()
+	if cfg == nil || cfg.Route == nil {
+		return ""
+	}
+	route := cfg.Route
+
+	labels := mergeLabels(alertMessage.CommonLabels, alertMessage.GroupLabels)
+	return matchRoute(route, labels)
+}
+
+func mergeLabels(sets ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, set := range sets {
+		for k, v := range set {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// matchRoute walks the route tree depth-first: a route matches when every match and
+// match_re entry is satisfied by labels, children are tried in list order, and the
+// first child that yields a receiver wins over its parent and its later siblings.
+func matchRoute(route *Route, labels map[string]string) string {
+	if !routeMatches(route, labels) {
+		return ""
+	}
+
+	for _, child := range route.Routes {
+		if name := matchRoute(child, labels); name != "" {
+			return name
+		}
+	}
+
+	return route.Receiver
+}
+
+func routeMatches(route *Route, labels map[string]string) bool {
+	for k, v := range route.Match {
+		if labels[k] != v {
+			return false
+		}
+	}
+	for k, pattern := range route.MatchRE {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false
+		}
+		if !re.MatchString(labels[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildNotifierFromReceiver turns a ReceiverConfig into a Notifier via the same
+// registry the legacy query-param flow uses, so both paths share one set of backends.
+func buildNotifierFromReceiver(rc ReceiverConfig) (Notifier, error) {
+	values := url.Values{}
+	for k, vs := range rc.Params {
+		values[k] = vs
+	}
+	if rc.Webhook != "" {
+		values.Set("webhook", rc.Webhook)
+	}
+	if rc.Secret != "" {
+		values.Set("secret", rc.Secret)
+	}
+	if len(rc.AtMobiles) > 0 {
+		values["atmobiles"] = rc.AtMobiles
+	}
+	values.Set("isatall", strconv.FormatBool(rc.AtAll))
+	values.Set("insecure", strconv.FormatBool(rc.Insecure))
+	if rc.RateLimitPerMinute > 0 {
+		values.Set("rate_limit", strconv.FormatFloat(rc.RateLimitPerMinute, 'f', -1, 64))
+	}
+
+	notifierName := rc.Notifier
+	if notifierName == "" {
+		notifierName = "dingtalk"
+	}
+	return buildNotifier(notifierName, values)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouteMatchesMatchAndMatchRE(t *testing.T) {
+	route := &Route{
+		Match:   map[string]string{"team": "sre"},
+		MatchRE: map[string]string{"severity": "^(warning|critical)$"},
+	}
+
+	if !routeMatches(route, map[string]string{"team": "sre", "severity": "critical"}) {
+		t.Fatalf("expected route to match labels satisfying both match and match_re")
+	}
+	if routeMatches(route, map[string]string{"team": "platform", "severity": "critical"}) {
+		t.Fatalf("expected route not to match on a differing match label")
+	}
+	if routeMatches(route, map[string]string{"team": "sre", "severity": "info"}) {
+		t.Fatalf("expected route not to match on a failing match_re")
+	}
+}
+
+func TestMatchRouteMostSpecificChildWins(t *testing.T) {
+	root := &Route{
+		Receiver: "default",
+		Routes: []*Route{
+			{
+				Receiver: "sre-critical",
+				Match:    map[string]string{"team": "sre", "severity": "critical"},
+			},
+			{
+				Receiver: "sre",
+				Match:    map[string]string{"team": "sre"},
+			},
+		},
+	}
+
+	got := matchRoute(root, map[string]string{"team": "sre", "severity": "critical"})
+	if got != "sre-critical" {
+		t.Fatalf("expected the more specific child route to win, got %q", got)
+	}
+
+	got = matchRoute(root, map[string]string{"team": "sre", "severity": "warning"})
+	if got != "sre" {
+		t.Fatalf("expected the less specific child route to win when the specific one doesn't match, got %q", got)
+	}
+
+	got = matchRoute(root, map[string]string{"team": "platform"})
+	if got != "default" {
+		t.Fatalf("expected the root receiver when no child matches, got %q", got)
+	}
+}
+
+func TestResolveReceiverNamePrecedence(t *testing.T) {
+	resetConfigForTest(t, &Config{
+		Receivers: []ReceiverConfig{{Name: "from-payload"}},
+		Route: &Route{
+			Receiver: "from-route",
+			Match:    map[string]string{"team": "sre"},
+		},
+	})
+
+	msg := Message{Receiver: "from-payload", CommonLabels: map[string]string{"team": "sre"}}
+
+	if got := resolveReceiverName(msg, "explicit"); got != "explicit" {
+		t.Fatalf("expected an explicit ?receiver= to win, got %q", got)
+	}
+	if got := resolveReceiverName(msg, ""); got != "from-payload" {
+		t.Fatalf("expected the payload's Receiver field to win over routing, got %q", got)
+	}
+
+	msg.Receiver = "unknown-receiver"
+	if got := resolveReceiverName(msg, ""); got != "from-route" {
+		t.Fatalf("expected an unknown payload Receiver to fall back to the route tree, got %q", got)
+	}
+}
+
+// TestResolveSendTargetsWithoutConfigDoesNotPanic is a regression test for the legacy
+// ?webhook=/?notifier= flow, which must keep working when no --config is loaded at all
+// (the package-level config stays nil in that case).
+func TestResolveSendTargetsWithoutConfigDoesNotPanic(t *testing.T) {
+	resetConfigForTest(t, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/dingtalk?webhook=http://example.invalid&isatall=false", strings.NewReader(`{}`))
+	if err := req.ParseForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	targets, err := resolveSendTargets(req, Message{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(targets) != 1 || targets[0].name != "dingtalk" {
+		t.Fatalf("expected the legacy flow to resolve a single dingtalk target, got %+v", targets)
+	}
+}
+
+// resetConfigForTest installs cfg as the package-level config for the duration of the
+// test, restoring whatever was loaded before.
+func resetConfigForTest(t *testing.T, cfg *Config) {
+	t.Helper()
+
+	configMu.Lock()
+	prevConfig, prevReceivers, prevPath := config, receiversByName, configPath
+
+	byName := map[string]ReceiverConfig{}
+	if cfg != nil {
+		for _, r := range cfg.Receivers {
+			byName[r.Name] = r
+		}
+	}
+	config, receiversByName, configPath = cfg, byName, ""
+	configMu.Unlock()
+
+	t.Cleanup(func() {
+		configMu.Lock()
+		config, receiversByName, configPath = prevConfig, prevReceivers, prevPath
+		configMu.Unlock()
+	})
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// alertFingerprint identifies an alert within a group and destination independently
+// of its current status, so a firing->resolved transition resolves to the same
+// StateStore key. targetScope is the resolved target name(s) (see
+// resolveSendTargets) this alert is being dispatched to, sorted and joined by the
+// caller: dedup is scoped per destination, not just per group, so routing the same
+// alert to a different channel - an operator changing a route's receiver, or a
+// caller varying ?notifier= - doesn't suppress that new channel's first send as a
+// false "repeat" of what a different channel already received.
+func alertFingerprint(groupKey, targetScope string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	h.Write([]byte(groupKey))
+	h.Write([]byte("|"))
+	h.Write([]byte(targetScope))
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte("="))
+		h.Write([]byte(labels[k]))
+		h.Write([]byte(";"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// filterAlerts drops alerts that shouldn't be notified about again: firing alerts
+// last sent less than repeatInterval ago, and resolved alerts that never fired or were
+// already announced resolved. Alerts that should be (re-)sent have their state
+// recorded in store before being returned. targetScope is passed through to
+// alertFingerprint so suppression is scoped per destination, not just per group.
+func filterAlerts(store StateStore, repeatInterval time.Duration, groupKey, targetScope string, alerts []Alert) ([]Alert, error) {
+	now := time.Now()
+	toSend := make([]Alert, 0, len(alerts))
+
+	for _, alert := range alerts {
+		key := alertFingerprint(groupKey, targetScope, alert.Labels)
+		prev, found, err := store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		switch alert.Status {
+		case "firing":
+			if found && prev.Status == "firing" && now.Sub(prev.LastSent) < repeatInterval {
+				continue
+			}
+			if err := store.Set(key, AlertState{Status: "firing", LastSent: now}); err != nil {
+				return nil, err
+			}
+		case "resolved":
+			if !found || prev.Status != "firing" {
+				continue
+			}
+			if err := store.Set(key, AlertState{Status: "resolved", LastSent: now}); err != nil {
+				return nil, err
+			}
+		}
+
+		toSend = append(toSend, alert)
+	}
+
+	return toSend, nil
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlertFingerprintStableAcrossStatus(t *testing.T) {
+	labels := map[string]string{"alertname": "HighCPU", "instance": "node-1"}
+	firing := alertFingerprint("group-1", "dingtalk", labels)
+	resolved := alertFingerprint("group-1", "dingtalk", labels)
+	if firing != resolved {
+		t.Fatalf("fingerprint should only depend on groupKey, targetScope and labels, got %q != %q", firing, resolved)
+	}
+
+	other := alertFingerprint("group-2", "dingtalk", labels)
+	if other == firing {
+		t.Fatalf("fingerprints for different group keys should differ")
+	}
+}
+
+func TestAlertFingerprintScopedByTarget(t *testing.T) {
+	labels := map[string]string{"alertname": "HighCPU"}
+
+	dingtalk := alertFingerprint("group-1", "dingtalk", labels)
+	slack := alertFingerprint("group-1", "slack", labels)
+	if dingtalk == slack {
+		t.Fatalf("expected fingerprints to differ across target scopes, so routing an alert to a new destination isn't suppressed as a repeat of what a different one already received")
+	}
+}
+
+func TestFilterAlertsDedupesFiringWithinRepeatInterval(t *testing.T) {
+	store := newMemoryStateStore()
+	labels := map[string]string{"alertname": "HighCPU"}
+	alerts := []Alert{{Status: "firing", Labels: labels}}
+
+	first, err := filterAlerts(store, time.Hour, "group-1", "dingtalk", alerts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first) != 1 {
+		t.Fatalf("expected the first firing alert to be sent, got %d", len(first))
+	}
+
+	second, err := filterAlerts(store, time.Hour, "group-1", "dingtalk", alerts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second) != 0 {
+		t.Fatalf("expected a repeat firing alert inside repeatInterval to be suppressed, got %d", len(second))
+	}
+}
+
+func TestFilterAlertsDoesNotSuppressADifferentTargetScope(t *testing.T) {
+	store := newMemoryStateStore()
+	labels := map[string]string{"alertname": "HighCPU"}
+	alerts := []Alert{{Status: "firing", Labels: labels}}
+
+	if _, err := filterAlerts(store, time.Hour, "group-1", "dingtalk", alerts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A route change (or a caller varying ?notifier=/?receiver=) sends the same
+	// firing alert to a new destination; it must not be suppressed just because
+	// "dingtalk" already saw it inside repeatInterval.
+	toSend, err := filterAlerts(store, time.Hour, "group-1", "slack", alerts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(toSend) != 1 {
+		t.Fatalf("expected a new target scope to get its own first send, got %d", len(toSend))
+	}
+}
+
+func TestFilterAlertsResendsFiringAfterRepeatInterval(t *testing.T) {
+	store := newMemoryStateStore()
+	key := alertFingerprint("group-1", "dingtalk", map[string]string{"alertname": "HighCPU"})
+	if err := store.Set(key, AlertState{Status: "firing", LastSent: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	alerts := []Alert{{Status: "firing", Labels: map[string]string{"alertname": "HighCPU"}}}
+	toSend, err := filterAlerts(store, time.Hour, "group-1", "dingtalk", alerts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(toSend) != 1 {
+		t.Fatalf("expected the firing alert to resend once repeatInterval has elapsed, got %d", len(toSend))
+	}
+}
+
+func TestFilterAlertsSendsResolvedOnceAfterFiring(t *testing.T) {
+	store := newMemoryStateStore()
+	labels := map[string]string{"alertname": "HighCPU"}
+
+	if _, err := filterAlerts(store, time.Hour, "group-1", "dingtalk", []Alert{{Status: "firing", Labels: labels}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resolved := []Alert{{Status: "resolved", Labels: labels}}
+	first, err := filterAlerts(store, time.Hour, "group-1", "dingtalk", resolved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first) != 1 {
+		t.Fatalf("expected the firing->resolved transition to send once, got %d", len(first))
+	}
+
+	second, err := filterAlerts(store, time.Hour, "group-1", "dingtalk", resolved)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second) != 0 {
+		t.Fatalf("expected an already-announced resolved alert not to resend, got %d", len(second))
+	}
+}
+
+func TestFilterAlertsDropsResolvedThatNeverFired(t *testing.T) {
+	store := newMemoryStateStore()
+	alerts := []Alert{{Status: "resolved", Labels: map[string]string{"alertname": "Unknown"}}}
+
+	toSend, err := filterAlerts(store, time.Hour, "group-1", "dingtalk", alerts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(toSend) != 0 {
+		t.Fatalf("expected a resolved alert with no prior firing state to be dropped, got %d", len(toSend))
+	}
+}
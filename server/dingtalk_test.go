@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSignedDingTalkWebhookSignatureIsVerifiable(t *testing.T) {
+	const secret = "SEC0000000000000000000000000000000000000000"
+
+	signed, err := signedDingTalkWebhook("https://oapi.dingtalk.com/robot/send?access_token=abc", secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("signed webhook is not a valid URL: %v", err)
+	}
+	if got := u.Query().Get("access_token"); got != "abc" {
+		t.Fatalf("expected the original access_token query param to survive signing, got %q", got)
+	}
+
+	timestamp := u.Query().Get("timestamp")
+	sign := u.Query().Get("sign")
+	if timestamp == "" || sign == "" {
+		t.Fatalf("expected both timestamp and sign to be set, got timestamp=%q sign=%q", timestamp, sign)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	want := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	if sign != want {
+		t.Fatalf("sign %q does not match HMAC-SHA256(timestamp+\"\\n\"+secret), want %q", sign, want)
+	}
+}
+
+func TestSignedDingTalkWebhookInvalidURL(t *testing.T) {
+	if _, err := signedDingTalkWebhook("://not-a-valid-url", "secret"); err == nil {
+		t.Fatalf("expected an error for an unparseable webhook URL")
+	}
+}
+
+func TestDingTalkNotifierRetriesOnRateLimitErrCode(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := dingTalkResponse{ErrCode: 0, ErrMsg: "ok"}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			resp = dingTalkResponse{ErrCode: dingTalkRateLimitErrCode, ErrMsg: "sent too fast"}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	n := &DingTalkNotifier{Webhook: server.URL, RateLimitPerMinute: 1_000_000}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := n.Notify(ctx, Message{CommonLabels: map[string]string{}}); err != nil {
+		t.Fatalf("expected Notify to succeed after retrying past the rate-limit errcode, got: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected exactly one retry (2 calls total), got %d calls", got)
+	}
+}
+
+func TestDingTalkNotifierReturnsNonRetryableErrCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(dingTalkResponse{ErrCode: 300001, ErrMsg: "invalid token"})
+	}))
+	defer server.Close()
+
+	n := &DingTalkNotifier{Webhook: server.URL, RateLimitPerMinute: 1_000_000}
+
+	if err := n.Notify(context.Background(), Message{CommonLabels: map[string]string{}}); err == nil {
+		t.Fatalf("expected Notify to return an error for a non-rate-limit errcode")
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/smtp"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	RegisterNotifier("email", newEmailNotifier)
+}
+
+// EmailNotifier delivers Alertmanager notifications over SMTP.
+type EmailNotifier struct {
+	SMTPHost string
+	SMTPPort string
+	From     string
+	Password string
+	To       []string
+}
+
+func newEmailNotifier(params url.Values) (Notifier, error) {
+	smtpHost := params.Get("smtp_host")
+	from := params.Get("from")
+	to := params["to"]
+	if smtpHost == "" || from == "" || len(to) == 0 {
+		return nil, errors.New("url arguments \"smtp_host\", \"from\" and \"to\" are required")
+	}
+	smtpPort := params.Get("smtp_port")
+	if smtpPort == "" {
+		smtpPort = "587"
+	}
+
+	return &EmailNotifier{
+		SMTPHost: smtpHost,
+		SMTPPort: smtpPort,
+		From:     from,
+		Password: params.Get("password"),
+		To:       to,
+	}, nil
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, alertMessage Message) error {
+	title, body, err := renderAlert(ctx, alertMessage)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		n.From, strings.Join(n.To, ","), title, body)
+
+	auth := smtp.PlainAuth("", n.From, n.Password, n.SMTPHost)
+	addr := n.SMTPHost + ":" + n.SMTPPort
+	if err := smtp.SendMail(addr, auth, n.From, n.To, []byte(msg)); err != nil {
+		return err
+	}
+
+	logger.Infow("alert sent", "notifier", "email", "to", n.To)
+	return nil
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+func init() {
+	RegisterNotifier("feishu", newFeishuNotifier)
+}
+
+type feishuText struct {
+	MsgType string `json:"msg_type"`
+	Content struct {
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// FeishuNotifier posts Alertmanager notifications to a Feishu/Lark group bot webhook.
+type FeishuNotifier struct {
+	Webhook string
+}
+
+func newFeishuNotifier(params url.Values) (Notifier, error) {
+	webhook := params.Get("webhook")
+	if webhook == "" {
+		return nil, errors.New("url argument \"webhook\" is null")
+	}
+
+	return &FeishuNotifier{Webhook: webhook}, nil
+}
+
+func (n *FeishuNotifier) Notify(ctx context.Context, alertMessage Message) error {
+	title, body, err := renderAlert(ctx, alertMessage)
+	if err != nil {
+		return err
+	}
+
+	msg := feishuText{MsgType: "text"}
+	msg.Content.Text = title + "\n" + body
+
+	return postJSON(ctx, "feishu", n.Webhook, msg)
+}
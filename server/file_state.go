@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// fileStateStore persists alert state as a single JSON file, so suppression and
+// resolved-alert tracking survive process restarts. The whole file is read into
+// memory once and rewritten on every Set, which is fine at this bridge's alert
+// volumes.
+type fileStateStore struct {
+	mu     sync.Mutex
+	path   string
+	states map[string]AlertState
+}
+
+// NewFileStateStore loads (or creates) a JSON-file-backed StateStore at path.
+func NewFileStateStore(path string) (StateStore, error) {
+	s := &fileStateStore{path: path, states: map[string]AlertState{}}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.states); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileStateStore) Get(key string) (AlertState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[key]
+	return state, ok, nil
+}
+
+func (s *fileStateStore) Set(key string, state AlertState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[key] = state
+
+	data, err := json.Marshal(s.states)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, data, 0o600)
+}
@@ -0,0 +1,15 @@
+package server
+
+import "go.uber.org/zap"
+
+// logger is the bridge's structured logger. Call sites attach group_key, alert_type
+// and webhook fields where known instead of formatting them into a message string.
+var logger = newLogger()
+
+func newLogger() *zap.SugaredLogger {
+	l, err := zap.NewProduction()
+	if err != nil {
+		l = zap.NewNop()
+	}
+	return l.Sugar()
+}
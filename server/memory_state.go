@@ -0,0 +1,27 @@
+package server
+
+import "sync"
+
+// memoryStateStore is the default StateStore: fast, but forgotten on restart.
+type memoryStateStore struct {
+	mu     sync.Mutex
+	states map[string]AlertState
+}
+
+func newMemoryStateStore() *memoryStateStore {
+	return &memoryStateStore{states: map[string]AlertState{}}
+}
+
+func (s *memoryStateStore) Get(key string) (AlertState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[key]
+	return state, ok, nil
+}
+
+func (s *memoryStateStore) Set(key string, state AlertState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[key] = state
+	return nil
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	alertsReceivedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "dingtalk_alert_server_alerts_received_total",
+		Help: "Alertmanager notifications received on /dingtalk.",
+	})
+
+	alertsForwardedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dingtalk_alert_server_alerts_forwarded_total",
+		Help: "Alerts successfully forwarded, per notifier backend.",
+	}, []string{"notifier"})
+
+	dingtalkErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dingtalk_alert_server_dingtalk_errors_total",
+		Help: "DingTalk API errors, by errcode.",
+	}, []string{"errcode"})
+
+	sendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dingtalk_alert_server_send_duration_seconds",
+		Help:    "Time spent delivering a notification, per notifier backend.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"notifier"})
+
+	// inFlightRequests stands in for queue depth: unless --group-wait is set, this
+	// bridge delivers notifications synchronously within the HTTP request, so the
+	// number of /dingtalk requests currently being processed is the closest analogue
+	// to a send queue. Alerts buffered for a batched flush are not counted.
+	inFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dingtalk_alert_server_in_flight_requests",
+		Help: "Number of /dingtalk requests currently being processed.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(alertsReceivedTotal, alertsForwardedTotal, dingtalkErrorsTotal, sendDuration, inFlightRequests)
+}
+
+// MetricsHandler exposes the bridge's own Prometheus metrics for a ServiceMonitor (or
+// any /metrics scraper) to pick up.
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+// HealthzHandler reports the process is up.
+func HealthzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// ReadyzHandler reports the process is ready to accept alerts. There's currently no
+// external dependency to probe, so this is equivalent to HealthzHandler, but kept as
+// a distinct endpoint so readiness gates (e.g. "wait for template/state dir to load")
+// can be added here without changing the route.
+func ReadyzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
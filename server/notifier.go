@@ -0,0 +1,66 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Notifier delivers a rendered Alertmanager notification to a single alert channel.
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// NotifierFactory builds a Notifier out of the form/query parameters of the incoming
+// /dingtalk request (webhook URL, secret, at-mobiles, ...). Backends register a factory
+// under their name from an init() func so new channels can be added without touching
+// ReceiveAndSend.
+type NotifierFactory func(params url.Values) (Notifier, error)
+
+var notifierRegistry = map[string]NotifierFactory{}
+
+// RegisterNotifier makes a notifier backend available under name.
+func RegisterNotifier(name string, factory NotifierFactory) {
+	notifierRegistry[name] = factory
+}
+
+func buildNotifier(name string, params url.Values) (Notifier, error) {
+	factory, ok := notifierRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier %q", name)
+	}
+	return factory(params)
+}
+
+// postJSON marshals payload and POSTs it to webhook, which is how every webhook
+// backend except dingtalk (which needs request signing and errcode-based retries)
+// delivers its notification; the email backend sends over SMTP instead. It logs under notifierName so failures are attributable to a
+// specific channel.
+func postJSON(ctx context.Context, notifierName, webhook string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		logger.Errorw(notifierName+" webhook returned non-200", "webhook", webhook, "status", resp.StatusCode)
+	}
+
+	logger.Infow("alert sent", "notifier", notifierName, "webhook", webhook)
+	return nil
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a minimal per-webhook rate limiter: it refills at rate tokens/sec up
+// to a burst of max tokens, and wait blocks until a token is available or ctx is done.
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	max    float64
+	rate   float64
+	last   time.Time
+}
+
+func newTokenBucket(max, rate float64) *tokenBucket {
+	return &tokenBucket{tokens: max, max: max, rate: rate, last: time.Now()}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.max, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+var (
+	rateLimitersMu sync.Mutex
+	rateLimiters   = map[string]*tokenBucket{}
+)
+
+// rateLimiterFor returns the shared token bucket for webhook, creating one bounded to
+// max requests per minute the first time it's seen.
+func rateLimiterFor(webhook string, maxPerMinute float64) *tokenBucket {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	b, ok := rateLimiters[webhook]
+	if !ok {
+		b = newTokenBucket(maxPerMinute, maxPerMinute/60)
+		rateLimiters[webhook] = b
+	}
+	return b
+}
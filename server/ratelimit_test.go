@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketAllowsBurstThenThrottles(t *testing.T) {
+	bucket := newTokenBucket(1, 100) // burst of 1, refills at 100 tokens/sec (~10ms/token)
+
+	start := time.Now()
+	if err := bucket.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error consuming the initial burst token: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Fatalf("expected the first call to consume the burst token without waiting, took %v", elapsed)
+	}
+
+	start = time.Now()
+	if err := bucket.wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error waiting for the next token: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Fatalf("expected the second call to throttle until a token refilled, took only %v", elapsed)
+	}
+}
+
+func TestTokenBucketWaitRespectsContextCancellation(t *testing.T) {
+	// A burst of 0 forces every call to wait for a refill; a tiny rate makes that
+	// refill take effectively forever, so cancellation is what ends the wait.
+	bucket := newTokenBucket(0, 0.0001)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := bucket.wait(ctx)
+	elapsed := time.Since(start)
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected wait to return the context's error, got %v", err)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("expected wait to return promptly once ctx was done, took %v", elapsed)
+	}
+}
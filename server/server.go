@@ -1,15 +1,13 @@
 package server
 
 import (
-	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
-	"strconv"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -34,230 +32,154 @@ type Message struct {
 	Alerts            []Alert           `json:"alerts"`
 }
 
-type At struct {
-	AtMobiles []string `json:"atMobiles"`
-	IsAtAll   bool     `json:"isAtAll"`
-}
-
-type DingTalkMarkdown struct {
-	MsgType  string   `json:"msgtype"`
-	At       At       `json:"at"`
-	Markdown Markdown `json:"markdown"`
-}
+// defaultNotifiers is used when the request doesn't select a backend explicitly, so
+// existing "?webhook=..." callers keep going to DingTalk.
+var defaultNotifiers = []string{"dingtalk"}
+
+// alertState and repeatInterval drive deduplication: a firing alert already sent
+// within repeatInterval is suppressed, and a resolved alert is only sent once, the
+// first time it's seen after having fired. Defaults to an in-memory store; override
+// with SetStateStore and SetRepeatInterval at startup.
+var (
+	alertState     StateStore = newMemoryStateStore()
+	repeatInterval            = 4 * time.Hour
+)
 
-type Markdown struct {
-	Title string `json:"title"`
-	Text  string `json:"text"`
+// SetStateStore swaps the backend used to deduplicate and track alert resolutions.
+func SetStateStore(store StateStore) {
+	alertState = store
 }
 
-const layout = "Jan 2, 2006 at 3:04pm (MST)"
-
-func SendToDingtalk(alertMessage Message, webhook string, atMobiles []string, isAtAll bool) error {
-	groupKey := alertMessage.CommonLabels["group_id"]
-	status := alertMessage.Status
-
-	message := fmt.Sprintf("## HCaaS告警\n\n ### 告警组：%s（状态：%s)\n\n", groupKey, status)
-
-	if _, ok := alertMessage.CommonLabels["alert_type"]; !ok {
-		return errors.New("alert type is null")
-	}
-
-	var description string
-	switch alertMessage.CommonLabels["alert_type"] {
-	case "event":
-		if _, ok := alertMessage.CommonLabels["event_type"]; !ok {
-			return errors.New("event_type is null in commonLabels")
-		}
-		if _, ok := alertMessage.GroupLabels["resource_kind"]; !ok {
-			return errors.New("resource kind is null in groupLabels")
-		}
-		description = fmt.Sprintf("\n > %s event of %s occuored\n\n", alertMessage.CommonLabels["event_type"], alertMessage.GroupLabels["resource_kind"])
-	case "systemService":
-		if _, ok := alertMessage.GroupLabels["component_name"]; !ok {
-			return errors.New("component name is null in groupLabels")
-		}
-		description = fmt.Sprintf("\n > The system component %s is not running\n\n", alertMessage.GroupLabels["event_type"])
-	case "nodeHealthy":
-		if _, ok := alertMessage.GroupLabels["node_name"]; !ok {
-			return errors.New("node name name is null in groupLabels")
-		}
-		description = fmt.Sprintf("\n > The kubelet on the node %s is not healthy\n\n", alertMessage.GroupLabels["node_name"])
-	case "nodeCPU":
-		if _, ok := alertMessage.GroupLabels["node_name"]; !ok {
-			return errors.New("node name name is null in groupLabels")
-		}
-		if _, ok := alertMessage.CommonLabels["cpu_threshold"]; !ok {
-			return errors.New("cpu threshold name is null in commonLabels")
-		}
-		description = fmt.Sprintf("\n > The CPU usage on the node %s is over %s%%\n\n", alertMessage.GroupLabels["node_name"], alertMessage.CommonLabels["cpu_threshold"])
-	case "nodeMemory":
-		if _, ok := alertMessage.GroupLabels["node_name"]; !ok {
-			return errors.New("node name name is null in groupLabels")
-		}
-		if _, ok := alertMessage.CommonLabels["mem_threshold"]; !ok {
-			return errors.New("mem threshold name is null in commonLabels")
-		}
-		description = fmt.Sprintf("\n > The memory usage on the node %s is over %s%%\n\n", alertMessage.GroupLabels["node_name"], alertMessage.CommonLabels["mem_threshold"])
-	case "podNotScheduled":
-		if _, ok := alertMessage.GroupLabels["pod_name"]; !ok {
-			return errors.New("pod name name is null in groupLabels")
-		}
-		var pod string
-		if namespace, ok := alertMessage.GroupLabels["namespace"]; ok {
-			pod = namespace + alertMessage.GroupLabels["pod_name"]
-		} else {
-			pod = alertMessage.GroupLabels["pod_name"]
-		}
-		description = fmt.Sprintf("\n > The Pod %s is not scheduled\n\n", pod)
-	case "podNotRunning":
-		if _, ok := alertMessage.GroupLabels["pod_name"]; !ok {
-			return errors.New("pod name name is null in groupLabels")
-		}
-		var pod string
-		if namespace, ok := alertMessage.GroupLabels["namespace"]; ok {
-			pod = namespace + alertMessage.GroupLabels["pod_name"]
-		} else {
-			pod = alertMessage.GroupLabels["pod_name"]
-		}
-		description = fmt.Sprintf("\n > The Pod %s is not running\n\n", pod)
-	case "podRestarts":
-		if _, ok := alertMessage.GroupLabels["pod_name"]; !ok {
-			return errors.New("pod name name is null in groupLabels")
-		}
-		if _, ok := alertMessage.CommonLabels["restart_times"]; !ok {
-			return errors.New("restart times is null in commonLabels")
-		}
-		if _, ok := alertMessage.CommonLabels["restart_interval"]; !ok {
-			return errors.New("restart interval is null in commonLabels")
-		}
-		var pod string
-		if namespace, ok := alertMessage.GroupLabels["namespace"]; ok {
-			pod = namespace + alertMessage.GroupLabels["pod_name"]
-		} else {
-			pod = alertMessage.GroupLabels["pod_name"]
-		}
-		description = fmt.Sprintf("\n > The Pod %s restarts %s times in %s sec\n\n", pod, alertMessage.CommonLabels["restart_times"], alertMessage.CommonLabels["restart_interval"])
-	case "workload":
-		if _, ok := alertMessage.GroupLabels["workload_name"]; !ok {
-			return errors.New("workload name is null in groupLabels")
-		}
-		if _, ok := alertMessage.CommonLabels["available_percentage"]; !ok {
-			return errors.New("available percentage is null in commonLabels")
-		}
-		var workload string
-		if namespace, ok := alertMessage.GroupLabels["workload_namespace"]; ok {
-			workload = namespace + alertMessage.GroupLabels["workload_name"]
-		} else {
-			workload = alertMessage.GroupLabels["workload_name"]
-		}
-		description = fmt.Sprintf("\n > The workload %s has available replicas less than %s%%\n\n", workload, alertMessage.CommonLabels["available_percentage"])
-	case "metric":
-		if _, ok := alertMessage.CommonLabels["alert_name"]; !ok {
-			return errors.New("alert name is null in commonLabels")
-		}
-		description = fmt.Sprintf("\n > The metric %s crossed the threshold\n\n", alertMessage.CommonLabels["alert_name"])
-	default:
-		return errors.New("invalid alert type")
-	}
-
-	message += description
-
-	for _, alert := range alertMessage.Alerts {
-		if alert.Status != "firing" {
-			continue
-		}
-		message += "-----\n"
-
-		for k, v := range alert.Labels {
-			message += fmt.Sprintf("- %s : %s\n", k, v)
-		}
-		message += fmt.Sprintf("- 起始时间：%s\n", alert.StartsAt.Format(layout))
-	}
-
-	dingtalkText := DingTalkMarkdown{
-		MsgType: "markdown",
-		At: At{
-			AtMobiles: atMobiles,
-			IsAtAll:   isAtAll,
-		},
-		Markdown: Markdown{
-			Title: fmt.Sprintf("HCaaS 告警组：%s（状态：%s）", groupKey, status),
-			Text:  message,
-		},
-	}
-
-	data, err := json.Marshal(dingtalkText)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewBuffer(data))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-		},
-	}
-	client := http.Client{Transport: tr}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-
-	if resp.StatusCode != 200 {
-		log.Printf("[ERROR] %s", resp.Header)
-	}
-
-	log.Printf("[INFO] Alert message sent to %s successfully", webhook)
-	_ = resp.Body.Close()
-	return nil
+// SetRepeatInterval controls how long a firing alert is suppressed before it is
+// re-sent, mirroring Alertmanager's repeat_interval.
+func SetRepeatInterval(d time.Duration) {
+	repeatInterval = d
 }
 
 func ReceiveAndSend(w http.ResponseWriter, req *http.Request) {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	inFlightRequests.Inc()
+	defer inFlightRequests.Dec()
+	alertsReceivedTotal.Inc()
 
 	body, err := ioutil.ReadAll(req.Body)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = fmt.Fprint(w, err)
-		log.Printf("[ERROR] %s", err)
+		logger.Errorw("failed to read request body", "error", err)
 		return
 	}
 
 	alertMessage := Message{}
 	_ = json.Unmarshal(body, &alertMessage)
 
+	groupKey := alertMessage.CommonLabels["group_id"]
+	alertType := alertMessage.CommonLabels["alert_type"]
+
 	err = req.ParseForm()
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = fmt.Fprint(w, err)
+		logger.Errorw("failed to parse form", "error", err, "group_key", groupKey)
 		return
 	}
 
-	if _, ok := req.Form["webhook"]; !ok {
-		log.Print("[ERROR] url argument \"webhook\" is null")
-		return
-	}
-	if _, ok := req.Form["isatall"]; !ok {
-		log.Print("[ERROR] url argument \"isatall\" is null")
+	targets, err := resolveSendTargets(req, alertMessage)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err)
+		logger.Errorw("failed to resolve notifier(s)", "error", err, "group_key", groupKey, "alert_type", alertType)
 		return
 	}
-	webhook := req.Form["webhook"][0]
-	atmobiles := req.Form["atmobiles"]
-	isatall, _ := strconv.ParseBool(req.Form["isatall"][0])
 
-	err = SendToDingtalk(alertMessage, webhook, atmobiles, isatall)
+	filtered, err := filterAlerts(alertState, repeatInterval, groupKey, targetScope(targets), alertMessage.Alerts)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = fmt.Fprint(w, err)
-		log.Printf("[ERROR] %s", err)
+		logger.Errorw("failed to filter alerts", "error", err, "group_key", groupKey, "alert_type", alertType)
+		return
+	}
+	if len(filtered) == 0 {
+		_, _ = fmt.Fprint(w, "No alerts to send (suppressed or already resolved)")
+		return
+	}
+	alertMessage.Alerts = filtered
+
+	if !enqueueOrSend(groupKey, targets, alertMessage) {
+		_, _ = fmt.Fprint(w, "Alert queued for batched delivery")
 		return
 	}
 
+	for _, target := range targets {
+		start := time.Now()
+		err := target.notifier.Notify(target.ctx, alertMessage)
+		sendDuration.WithLabelValues(target.name).Observe(time.Since(start).Seconds())
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprint(w, err)
+			logger.Errorw("failed to send alert", "error", err, "notifier", target.name, "group_key", groupKey, "alert_type", alertType)
+			return
+		}
+		alertsForwardedTotal.WithLabelValues(target.name).Inc()
+	}
+
 	_, _ = fmt.Fprint(w, "Alert sent successfully")
 }
+
+// sendTarget pairs a built Notifier with its display name and the context it should
+// be sent with (carrying a per-receiver template_dir override, if any).
+type sendTarget struct {
+	name     string
+	notifier Notifier
+	ctx      context.Context
+}
+
+// targetScope turns a resolved set of send targets into the string dedup.go's
+// alertFingerprint uses to scope suppression per destination, so routing the same
+// alert to a different receiver/notifier doesn't get wrongly suppressed as a repeat
+// of what a different destination already received.
+func targetScope(targets []sendTarget) string {
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.name
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// resolveSendTargets picks how to notify for this request: a config-file receiver
+// (named explicitly via ?receiver=, via the Alertmanager payload's Receiver field, or
+// matched by the routing tree) takes priority; otherwise it falls back to the legacy
+// ?notifier=/?webhook= query-parameter flow.
+func resolveSendTargets(req *http.Request, alertMessage Message) ([]sendTarget, error) {
+	if name := resolveReceiverName(alertMessage, req.Form.Get("receiver")); name != "" {
+		rc, ok := receiverByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown receiver %q", name)
+		}
+
+		notifier, err := buildNotifierFromReceiver(rc)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx := contextWithTemplateDir(req.Context(), rc.TemplateDir)
+		return []sendTarget{{name: rc.Name, notifier: notifier, ctx: ctx}}, nil
+	}
+
+	names := defaultNotifiers
+	if raw, ok := req.Form["notifier"]; ok && len(raw) > 0 {
+		names = strings.Split(raw[0], ",")
+	}
+
+	targets := make([]sendTarget, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+
+		notifier, err := buildNotifier(name, req.Form)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, sendTarget{name: name, notifier: notifier, ctx: req.Context()})
+	}
+	return targets, nil
+}
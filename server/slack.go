@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+func init() {
+	RegisterNotifier("slack", newSlackNotifier)
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// SlackNotifier posts Alertmanager notifications to a Slack incoming webhook.
+type SlackNotifier struct {
+	Webhook string
+}
+
+func newSlackNotifier(params url.Values) (Notifier, error) {
+	webhook := params.Get("webhook")
+	if webhook == "" {
+		return nil, errors.New("url argument \"webhook\" is null")
+	}
+
+	return &SlackNotifier{Webhook: webhook}, nil
+}
+
+func (n *SlackNotifier) Notify(ctx context.Context, alertMessage Message) error {
+	title, body, err := renderAlert(ctx, alertMessage)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, "slack", n.Webhook, slackMessage{Text: title + "\n" + body})
+}
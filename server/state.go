@@ -0,0 +1,21 @@
+package server
+
+import "time"
+
+// AlertState is the last known status and notification time for a single alert
+// fingerprint.
+type AlertState struct {
+	Status   string    `json:"status"`
+	LastSent time.Time `json:"lastSent"`
+}
+
+// StateStore tracks AlertState per alert fingerprint so repeated firing alerts can be
+// suppressed within a repeat_interval and firing->resolved transitions detected.
+// Built-in backends are an in-memory store and a JSON file store, not BoltDB or
+// Redis: both would need a client library this deployment doesn't otherwise
+// depend on, and the interface already lets either be added later without
+// touching the dedup logic in dedup.go.
+type StateStore interface {
+	Get(key string) (AlertState, bool, error)
+	Set(key string, state AlertState) error
+}
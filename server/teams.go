@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+func init() {
+	RegisterNotifier("teams", newTeamsNotifier)
+}
+
+type teamsMessageCard struct {
+	Type    string `json:"@type"`
+	Context string `json:"@context"`
+	Summary string `json:"summary"`
+	Text    string `json:"text"`
+}
+
+// TeamsNotifier posts Alertmanager notifications to a Microsoft Teams incoming webhook
+// connector as a MessageCard.
+type TeamsNotifier struct {
+	Webhook string
+}
+
+func newTeamsNotifier(params url.Values) (Notifier, error) {
+	webhook := params.Get("webhook")
+	if webhook == "" {
+		return nil, errors.New("url argument \"webhook\" is null")
+	}
+
+	return &TeamsNotifier{Webhook: webhook}, nil
+}
+
+func (n *TeamsNotifier) Notify(ctx context.Context, alertMessage Message) error {
+	title, body, err := renderAlert(ctx, alertMessage)
+	if err != nil {
+		return err
+	}
+
+	card := teamsMessageCard{
+		Type:    "MessageCard",
+		Context: "http://schema.org/extensions",
+		Summary: title,
+		Text:    body,
+	}
+
+	return postJSON(ctx, "teams", n.Webhook, card)
+}
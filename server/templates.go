@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplatesFS embed.FS
+
+var templateFuncs = template.FuncMap{
+	"label":         labelOrDefault,
+	"humanizeBytes": humanizeBytes,
+	"formatTime":    func(t time.Time) string { return t.Format(layout) },
+}
+
+// templateDir, when set via SetTemplateDir, is checked for a "<name>.tmpl" override
+// before falling back to the built-in templates embedded in the binary.
+var templateDir string
+
+// SetTemplateDir points alert rendering at a directory of user-supplied *.tmpl
+// overrides (one per alert_type, plus an optional _common.tmpl), so operators can
+// restyle or translate notifications without recompiling.
+func SetTemplateDir(dir string) {
+	templateDir = dir
+}
+
+type templateDirCtxKey struct{}
+
+// contextWithTemplateDir lets a single request override the process-wide template
+// directory, e.g. the per-receiver template_dir set in the routing config.
+func contextWithTemplateDir(ctx context.Context, dir string) context.Context {
+	if dir == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, templateDirCtxKey{}, dir)
+}
+
+func templateDirFromContext(ctx context.Context) string {
+	if dir, ok := ctx.Value(templateDirCtxKey{}).(string); ok {
+		return dir
+	}
+	return templateDir
+}
+
+// labelOrDefault looks up key in labels, falling back to def instead of erroring when
+// the label is absent - templates render a placeholder rather than failing the alert.
+func labelOrDefault(key string, labels map[string]string, def string) string {
+	if v, ok := labels[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func humanizeBytes(n float64) string {
+	const unit = 1024.0
+	if n < unit {
+		return fmt.Sprintf("%.0fB", n)
+	}
+	div, exp := unit, 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", n/div, "KMGTPE"[exp])
+}
+
+// renderAlert renders the notification title and body for alertMessage using the
+// text/template named after its alert_type, falling back to "default.tmpl" when no
+// matching template is registered.
+func renderAlert(ctx context.Context, alertMessage Message) (title string, body string, err error) {
+	groupKey := alertMessage.CommonLabels["group_id"]
+	status := alertMessage.Status
+	if allResolved(alertMessage.Alerts) {
+		status = "resolved"
+	}
+
+	tmpl, err := loadAlertTemplate(ctx, alertMessage.CommonLabels["alert_type"])
+	if err != nil {
+		return "", "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alertMessage); err != nil {
+		return "", "", err
+	}
+
+	title = fmt.Sprintf("HCaaS 告警组：%s（状态：%s）", groupKey, status)
+	header := fmt.Sprintf("## HCaaS告警\n\n ### 告警组：%s（状态：%s)\n\n", groupKey, status)
+	return title, header + buf.String(), nil
+}
+
+// allResolved reports whether every alert in a (possibly already-filtered) batch is a
+// resolved transition, so the notification header can say so even when the overall
+// Alertmanager Message.Status hasn't been updated yet.
+func allResolved(alerts []Alert) bool {
+	if len(alerts) == 0 {
+		return false
+	}
+	for _, a := range alerts {
+		if a.Status != "resolved" {
+			return false
+		}
+	}
+	return true
+}
+
+func loadAlertTemplate(ctx context.Context, alertType string) (*template.Template, error) {
+	name := alertType + ".tmpl"
+
+	specific, err := readTemplateFile(ctx, name)
+	if err != nil {
+		name = "default.tmpl"
+		specific, err = readTemplateFile(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("no template registered for alert_type %q: %w", alertType, err)
+		}
+	}
+
+	common, err := readTemplateFile(ctx, "_common.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(common)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Parse(specific)
+}
+
+// readTemplateFile returns the contents of name, preferring a file under the
+// request's template directory (process-wide, or a per-receiver override carried on
+// ctx) over the built-in embedded copy.
+func readTemplateFile(ctx context.Context, name string) (string, error) {
+	if dir := templateDirFromContext(ctx); dir != "" {
+		if data, err := ioutil.ReadFile(filepath.Join(dir, name)); err == nil {
+			return string(data), nil
+		}
+	}
+
+	data, err := defaultTemplatesFS.ReadFile("templates/" + name)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
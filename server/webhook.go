@@ -0,0 +1,30 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+func init() {
+	RegisterNotifier("webhook", newGenericWebhookNotifier)
+}
+
+// GenericWebhookNotifier POSTs the raw Alertmanager Message as JSON to an arbitrary
+// URL, for channels that don't have a dedicated backend yet.
+type GenericWebhookNotifier struct {
+	URL string
+}
+
+func newGenericWebhookNotifier(params url.Values) (Notifier, error) {
+	webhook := params.Get("webhook")
+	if webhook == "" {
+		return nil, errors.New("url argument \"webhook\" is null")
+	}
+
+	return &GenericWebhookNotifier{URL: webhook}, nil
+}
+
+func (n *GenericWebhookNotifier) Notify(ctx context.Context, alertMessage Message) error {
+	return postJSON(ctx, "webhook", n.URL, alertMessage)
+}
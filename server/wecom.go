@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+func init() {
+	RegisterNotifier("wecom", newWeComNotifier)
+}
+
+type weComMarkdown struct {
+	MsgType  string `json:"msgtype"`
+	Markdown struct {
+		Content string `json:"content"`
+	} `json:"markdown"`
+}
+
+// WeComNotifier posts Alertmanager notifications to a WeCom (Enterprise WeChat) group
+// robot webhook. The payload shape is DingTalk's markdown message minus the "at" block,
+// which WeCom expresses as "<@mobile>" tokens inside the content instead.
+type WeComNotifier struct {
+	Webhook   string
+	AtMobiles []string
+}
+
+func newWeComNotifier(params url.Values) (Notifier, error) {
+	webhook := params.Get("webhook")
+	if webhook == "" {
+		return nil, errors.New("url argument \"webhook\" is null")
+	}
+
+	return &WeComNotifier{
+		Webhook:   webhook,
+		AtMobiles: params["atmobiles"],
+	}, nil
+}
+
+func (n *WeComNotifier) Notify(ctx context.Context, alertMessage Message) error {
+	_, body, err := renderAlert(ctx, alertMessage)
+	if err != nil {
+		return err
+	}
+
+	for _, mobile := range n.AtMobiles {
+		body += "\n<@" + mobile + ">"
+	}
+
+	msg := weComMarkdown{MsgType: "markdown"}
+	msg.Markdown.Content = body
+
+	return postJSON(ctx, "wecom", n.Webhook, msg)
+}